@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"git.sr.ht/~spc/go-log"
+)
+
+// parseExpositionFormat parses the Prometheus text exposition format,
+// ignoring HELP/TYPE comments. It intentionally only understands the
+// subset needed for filtering by metric name: "name{labels} value[ ts]" and
+// "name value[ ts]", one sample per line. A line it can't make sense of
+// (a summary/histogram quirk, a stray blank field) is skipped rather than
+// discarding the rest of the scrape.
+func parseExpositionFormat(r io.Reader) ([]Sample, error) {
+	var samples []Sample
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		sample, err := parseExpositionLine(line)
+		if err != nil {
+			log.Tracef("skipping unparseable metric line %q: %v", line, err)
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+func parseExpositionLine(line string) (Sample, error) {
+	name := line
+	labels := map[string]string{}
+	rest := ""
+
+	if brace := strings.IndexByte(line, '{'); brace >= 0 {
+		closeBrace := strings.LastIndexByte(line, '}')
+		if closeBrace < brace {
+			return Sample{}, fmt.Errorf("unbalanced labels")
+		}
+		name = strings.TrimSpace(line[:brace])
+		labels = parseLabels(line[brace+1 : closeBrace])
+		rest = strings.TrimSpace(line[closeBrace+1:])
+	} else {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return Sample{}, fmt.Errorf("expected at least 'name value'")
+		}
+		name = fields[0]
+		rest = strings.Join(fields[1:], " ")
+	}
+
+	value, err := parseValue(rest)
+	if err != nil {
+		return Sample{}, err
+	}
+	return Sample{Name: name, Labels: labels, Value: value}, nil
+}
+
+// parseValue reads the sample's value, the first field of rest. A trailing
+// millisecond timestamp (the optional third exposition-format field) is
+// accepted and ignored - Sample.Timestamp is stamped by the scraper itself.
+func parseValue(rest string) (float64, error) {
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("missing value")
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse value: %w", err)
+	}
+	return value, nil
+}
+
+func parseLabels(raw string) map[string]string {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		labels[kv[0]] = strings.Trim(kv[1], `"`)
+	}
+	return labels
+}
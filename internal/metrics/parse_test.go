@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseExpositionFormat_NoLabels(t *testing.T) {
+	samples, err := parseExpositionFormat(strings.NewReader("http_requests_total 1027\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(samples) != 1 || samples[0].Name != "http_requests_total" || samples[0].Value != 1027 {
+		t.Fatalf("unexpected samples: %+v", samples)
+	}
+}
+
+func TestParseExpositionFormat_WithTimestamp(t *testing.T) {
+	samples, err := parseExpositionFormat(strings.NewReader("http_requests_total 1027 1395066363000\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(samples) != 1 || samples[0].Value != 1027 {
+		t.Fatalf("expected the timestamp field to be ignored, got: %+v", samples)
+	}
+}
+
+func TestParseExpositionFormat_LabelsWithTimestampAndQuotes(t *testing.T) {
+	samples, err := parseExpositionFormat(strings.NewReader(
+		`http_requests_total{method="post",code="200"} 1027 1395066363000` + "\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(samples) != 1 {
+		t.Fatalf("expected 1 sample, got %d", len(samples))
+	}
+	s := samples[0]
+	if s.Name != "http_requests_total" || s.Value != 1027 {
+		t.Fatalf("unexpected sample: %+v", s)
+	}
+	if s.Labels["method"] != "post" || s.Labels["code"] != "200" {
+		t.Fatalf("expected unquoted label values, got: %+v", s.Labels)
+	}
+}
+
+func TestParseExpositionFormat_SkipsBadLinesRatherThanAborting(t *testing.T) {
+	input := strings.Join([]string{
+		"# HELP http_requests_total total requests",
+		"# TYPE http_requests_total counter",
+		"this line is garbage",
+		"http_requests_total{method=\"post\"} 1027",
+		"another bad {{{ line",
+		"http_requests_total{method=\"get\"} 2048",
+	}, "\n")
+
+	samples, err := parseExpositionFormat(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("expected the 2 good samples to survive the bad lines, got %d: %+v", len(samples), samples)
+	}
+}
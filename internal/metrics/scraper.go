@@ -0,0 +1,217 @@
+// Package metrics scrapes the Prometheus endpoints exposed by running
+// workloads, filters the result against the operator-configured allow list,
+// and forwards what's left upstream.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"git.sr.ht/~spc/go-log"
+	"github.com/jakub-dzon/k4e-operator/models"
+)
+
+const defaultScrapeInterval = 60 * time.Second
+
+// Target is a single workload's scrapeable Prometheus endpoint, discovered
+// from the `prometheus.io/*` annotations toPod sets on its pod.
+type Target struct {
+	WorkloadName string
+	Address      string // host:port
+	Path         string // defaults to "/metrics"
+}
+
+// Sample is a single scraped metric sample. Samples are allow-listed by
+// Scraper.filter before they are forwarded.
+type Sample struct {
+	WorkloadName string
+	Name         string
+	Labels       map[string]string
+	Value        float64
+	Timestamp    time.Time
+}
+
+// Dispatcher forwards scraped samples upstream, e.g. as a "data_message" on
+// the existing heartbeat/dispatcher channel.
+type Dispatcher interface {
+	SendData(dataType string, samples []Sample) error
+}
+
+// dataMessageType is the dispatcher message type scraped samples are sent
+// as.
+const dataMessageType = "data_message"
+
+// Scraper owns one goroutine per scrape target and re-filters every sample
+// against the latest configuration before forwarding it.
+type Scraper struct {
+	dispatcher Dispatcher
+
+	lock       sync.Mutex
+	disabled   bool
+	interval   time.Duration
+	allowNames map[string]struct{}
+	allowAll   bool
+	cancelFns  map[string]context.CancelFunc
+	targets    map[string]Target
+}
+
+// NewScraper creates a Scraper that forwards filtered samples through
+// dispatcher. It starts with scraping disabled until Configure is called.
+func NewScraper(dispatcher Dispatcher) *Scraper {
+	return &Scraper{
+		dispatcher: dispatcher,
+		disabled:   true,
+		interval:   defaultScrapeInterval,
+		cancelFns:  make(map[string]context.CancelFunc),
+		targets:    make(map[string]Target),
+	}
+}
+
+// Configure applies cfg and the current set of scrape targets. It reconciles
+// the running scrape goroutines against targets without restarting ones
+// that are unchanged, so in-flight scrapes aren't lost on every
+// WorkloadManager.Update.
+func (s *Scraper) Configure(cfg models.SystemMetricsConfiguration, targets []Target) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.disabled = cfg.Disabled
+	if cfg.Interval > 0 {
+		s.interval = time.Duration(cfg.Interval) * time.Second
+	}
+	s.allowAll = cfg.AllowList == nil
+	s.allowNames = make(map[string]struct{})
+	if cfg.AllowList != nil {
+		for _, name := range cfg.AllowList.Names {
+			s.allowNames[name] = struct{}{}
+		}
+	}
+
+	wanted := make(map[string]struct{}, len(targets))
+	for _, target := range targets {
+		wanted[target.WorkloadName] = struct{}{}
+	}
+
+	// Stop scraping workloads that were removed or redeployed without a
+	// metrics endpoint anymore.
+	for name, cancel := range s.cancelFns {
+		if _, ok := wanted[name]; !ok {
+			cancel()
+			delete(s.cancelFns, name)
+		}
+	}
+
+	if s.disabled {
+		for name, cancel := range s.cancelFns {
+			cancel()
+			delete(s.cancelFns, name)
+		}
+		return
+	}
+
+	for _, target := range targets {
+		if cancel, running := s.cancelFns[target.WorkloadName]; running {
+			if s.targets[target.WorkloadName] == target {
+				continue
+			}
+			cancel()
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		s.cancelFns[target.WorkloadName] = cancel
+		s.targets[target.WorkloadName] = target
+		go s.run(ctx, target)
+	}
+}
+
+// Stop tears down every in-flight scrape goroutine.
+func (s *Scraper) Stop() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for name, cancel := range s.cancelFns {
+		cancel()
+		delete(s.cancelFns, name)
+	}
+}
+
+func (s *Scraper) run(ctx context.Context, target Target) {
+	for {
+		s.lock.Lock()
+		interval := s.interval
+		s.lock.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		samples, err := s.scrape(ctx, target, interval)
+		if err != nil {
+			log.Errorf("cannot scrape workload '%s': %v", target.WorkloadName, err)
+			continue
+		}
+		samples = s.filter(samples)
+		if len(samples) == 0 {
+			continue
+		}
+		if err := s.dispatcher.SendData(dataMessageType, samples); err != nil {
+			log.Errorf("cannot forward metrics for workload '%s': %v", target.WorkloadName, err)
+		}
+	}
+}
+
+// scrape fetches and parses a single target. The request is bound to ctx and
+// the client is given a timeout no longer than the scrape interval, so a
+// hung workload endpoint can't wedge this goroutine (or Stop/Deregister,
+// which cancel ctx to tear it down) indefinitely.
+func (s *Scraper) scrape(ctx context.Context, target Target, interval time.Duration) ([]Sample, error) {
+	path := target.Path
+	if path == "" {
+		path = "/metrics"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s%s", target.Address, path), nil)
+	if err != nil {
+		return nil, err
+	}
+	client := http.Client{Timeout: interval}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	samples, err := parseExpositionFormat(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	for i := range samples {
+		samples[i].WorkloadName = target.WorkloadName
+	}
+	return samples, nil
+}
+
+// filter drops any sample whose metric name isn't allow-listed. A nil
+// allow list (allowAll) lets everything through; an empty one lets nothing
+// through.
+func (s *Scraper) filter(samples []Sample) []Sample {
+	s.lock.Lock()
+	allowAll := s.allowAll
+	allowNames := s.allowNames
+	s.lock.Unlock()
+
+	if allowAll {
+		return samples
+	}
+	filtered := samples[:0]
+	for _, sample := range samples {
+		if _, ok := allowNames[sample.Name]; ok {
+			filtered = append(filtered, sample)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/jakub-dzon/k4e-operator/models"
+)
+
+func samplesNamed(names ...string) []Sample {
+	samples := make([]Sample, len(names))
+	for i, name := range names {
+		samples[i] = Sample{Name: name, Value: 1}
+	}
+	return samples
+}
+
+func TestScraperFilter_NilAllowListAllowsEverything(t *testing.T) {
+	s := NewScraper(nil)
+	s.Configure(models.SystemMetricsConfiguration{}, nil)
+
+	filtered := s.filter(samplesNamed("cpu", "memory"))
+	if len(filtered) != 2 {
+		t.Fatalf("expected nil allow list to let everything through, got %+v", filtered)
+	}
+}
+
+func TestScraperFilter_EmptyAllowListAllowsNothing(t *testing.T) {
+	s := NewScraper(nil)
+	s.Configure(models.SystemMetricsConfiguration{AllowList: &models.MetricsAllowList{Names: []string{}}}, nil)
+
+	filtered := s.filter(samplesNamed("cpu", "memory"))
+	if len(filtered) != 0 {
+		t.Fatalf("expected empty allow list to drop everything, got %+v", filtered)
+	}
+}
+
+func TestScraperFilter_OnlyAllowListedNamesPass(t *testing.T) {
+	s := NewScraper(nil)
+	s.Configure(models.SystemMetricsConfiguration{AllowList: &models.MetricsAllowList{Names: []string{"cpu"}}}, nil)
+
+	filtered := s.filter(samplesNamed("cpu", "memory"))
+	if len(filtered) != 1 || filtered[0].Name != "cpu" {
+		t.Fatalf("expected only 'cpu' to pass, got %+v", filtered)
+	}
+}
@@ -0,0 +1,21 @@
+// Package api carries the data WorkloadManager exposes about deployed
+// workloads up to its callers, independent of whatever container runtime
+// backs WorkloadWrapper.
+package api
+
+// ContainerHealth is a single container's last-known healthcheck result.
+type ContainerHealth struct {
+	Healthy bool
+	Log     string
+}
+
+// WorkloadInfo describes a single deployed workload as returned by
+// WorkloadWrapper.List / WorkloadManager.ListWorkloads.
+type WorkloadInfo struct {
+	Id     string
+	Name   string
+	Status string
+	// Health is keyed by container name; a container with no configured
+	// healthcheck has no entry here.
+	Health map[string]ContainerHealth
+}
@@ -0,0 +1,105 @@
+package workload
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// CopyOptions controls how CopyToWorkload/CopyFromWorkload move files
+// between the host and a running workload's container, mirroring the knobs
+// `podman cp` exposes.
+type CopyOptions struct {
+	// Overwrite allows an existing destination to be replaced. When false,
+	// the copy fails rather than clobbering existing content.
+	Overwrite bool
+	// PreserveOwnership keeps the source file's owner/group instead of
+	// mapping it to the destination container's default user.
+	PreserveOwnership bool
+	// NoDereference copies symlinks themselves rather than the files they
+	// point to.
+	NoDereference bool
+	// Progress, if set, is called as the tar stream backing the copy
+	// advances, so an RPC caller can report progress back to the operator.
+	Progress func(bytesCopied, totalBytes int64)
+}
+
+// CopyDirection tells WorkloadWrapper.Copy which way the tar stream flows,
+// since the host path and the container path alone don't say which end is
+// the source.
+type CopyDirection string
+
+const (
+	CopyDirectionToWorkload   CopyDirection = "to"
+	CopyDirectionFromWorkload CopyDirection = "from"
+)
+
+// CopyResult reports how much data a copy moved, so callers (and the RPC
+// layer wrapping them) can surface size back to the operator.
+type CopyResult struct {
+	BytesCopied int64
+}
+
+// CopyToWorkload copies srcHostPath into dstContainerPath inside the named
+// workload's container, streaming directories recursively as a tar archive.
+func (w *WorkloadManager) CopyToWorkload(name, srcHostPath, dstContainerPath string, opts CopyOptions) (CopyResult, error) {
+	dstContainerPath, err := sanitizeContainerPath(dstContainerPath)
+	if err != nil {
+		return CopyResult{}, fmt.Errorf("cannot copy to workload '%s': %w", name, err)
+	}
+	result, err := w.workloads.Copy(name, srcHostPath, dstContainerPath, CopyDirectionToWorkload, opts.Overwrite, opts.PreserveOwnership, opts.NoDereference, opts.Progress)
+	if err != nil {
+		return CopyResult{}, fmt.Errorf("cannot copy '%s' to workload '%s:%s': %w", srcHostPath, name, dstContainerPath, err)
+	}
+	return result, nil
+}
+
+// CopyFromWorkload copies srcContainerPath out of the named workload's
+// container into dstHostPath on the host, streaming directories recursively
+// as a tar archive.
+func (w *WorkloadManager) CopyFromWorkload(name, srcContainerPath, dstHostPath string, opts CopyOptions) (CopyResult, error) {
+	srcContainerPath, err := sanitizeContainerPath(srcContainerPath)
+	if err != nil {
+		return CopyResult{}, fmt.Errorf("cannot copy from workload '%s': %w", name, err)
+	}
+	result, err := w.workloads.Copy(name, dstHostPath, srcContainerPath, CopyDirectionFromWorkload, opts.Overwrite, opts.PreserveOwnership, opts.NoDereference, opts.Progress)
+	if err != nil {
+		return CopyResult{}, fmt.Errorf("cannot copy '%s:%s' to '%s': %w", name, srcContainerPath, dstHostPath, err)
+	}
+	return result, nil
+}
+
+// sanitizeContainerPath anchors containerPath at the container's rootfs,
+// clamping any "../" segments at the root so the resulting path can never
+// escape it once joined with the container's filesystem.
+func sanitizeContainerPath(containerPath string) (string, error) {
+	if containerPath == "" {
+		return "", fmt.Errorf("container path must not be empty")
+	}
+	return filepath.Clean("/" + containerPath), nil
+}
+
+// CopyRequest is the device-worker RPC surface's view of a copy operation:
+// the operator names a workload, a direction, the two paths and the options
+// above, and HandleCopyRequest dispatches to CopyToWorkload/CopyFromWorkload
+// without the RPC layer needing to know about either directly.
+type CopyRequest struct {
+	WorkloadName  string
+	Direction     CopyDirection
+	HostPath      string
+	ContainerPath string
+	Options       CopyOptions
+}
+
+// HandleCopyRequest is the single entry point the device-worker RPC surface
+// calls for both push and pull, so CopyToWorkload/CopyFromWorkload are
+// reachable from outside the workload package.
+func (w *WorkloadManager) HandleCopyRequest(req CopyRequest) (CopyResult, error) {
+	switch req.Direction {
+	case CopyDirectionToWorkload:
+		return w.CopyToWorkload(req.WorkloadName, req.HostPath, req.ContainerPath, req.Options)
+	case CopyDirectionFromWorkload:
+		return w.CopyFromWorkload(req.WorkloadName, req.ContainerPath, req.HostPath, req.Options)
+	default:
+		return CopyResult{}, fmt.Errorf("unknown copy direction '%s'", req.Direction)
+	}
+}
@@ -0,0 +1,116 @@
+package workload
+
+import (
+	"testing"
+)
+
+func TestCopyToWorkload_DirectionAndPaths(t *testing.T) {
+	ww := newFakeWorkloadWrapper()
+	var gotDir CopyDirection
+	var gotHostPath, gotContainerPath string
+	ww.copyFn = func(workloadName, hostPath, containerPath string, dir CopyDirection, overwrite, preserveOwnership, noDereference bool, progress func(int64, int64)) (CopyResult, error) {
+		gotDir = dir
+		gotHostPath = hostPath
+		gotContainerPath = containerPath
+		return CopyResult{BytesCopied: 42}, nil
+	}
+
+	w, err := NewWorkloadManagerWithParams(t.TempDir(), ww, "device-config", "/tmp/device-config", nil)
+	if err != nil {
+		t.Fatalf("NewWorkloadManagerWithParams: %v", err)
+	}
+
+	result, err := w.CopyToWorkload("workload", "/host/src", "container/dst", CopyOptions{})
+	if err != nil {
+		t.Fatalf("CopyToWorkload: %v", err)
+	}
+	if gotDir != CopyDirectionToWorkload {
+		t.Fatalf("expected direction %q, got %q", CopyDirectionToWorkload, gotDir)
+	}
+	if gotHostPath != "/host/src" || gotContainerPath != "/container/dst" {
+		t.Fatalf("expected host='/host/src' container='/container/dst', got host=%q container=%q", gotHostPath, gotContainerPath)
+	}
+	if result.BytesCopied != 42 {
+		t.Fatalf("expected BytesCopied=42, got %d", result.BytesCopied)
+	}
+}
+
+func TestCopyFromWorkload_DirectionAndPaths(t *testing.T) {
+	ww := newFakeWorkloadWrapper()
+	var gotDir CopyDirection
+	var gotHostPath, gotContainerPath string
+	ww.copyFn = func(workloadName, hostPath, containerPath string, dir CopyDirection, overwrite, preserveOwnership, noDereference bool, progress func(int64, int64)) (CopyResult, error) {
+		gotDir = dir
+		gotHostPath = hostPath
+		gotContainerPath = containerPath
+		return CopyResult{BytesCopied: 7}, nil
+	}
+
+	w, err := NewWorkloadManagerWithParams(t.TempDir(), ww, "device-config", "/tmp/device-config", nil)
+	if err != nil {
+		t.Fatalf("NewWorkloadManagerWithParams: %v", err)
+	}
+
+	result, err := w.CopyFromWorkload("workload", "container/src", "/host/dst", CopyOptions{})
+	if err != nil {
+		t.Fatalf("CopyFromWorkload: %v", err)
+	}
+	if gotDir != CopyDirectionFromWorkload {
+		t.Fatalf("expected direction %q, got %q", CopyDirectionFromWorkload, gotDir)
+	}
+	if gotHostPath != "/host/dst" || gotContainerPath != "/container/src" {
+		t.Fatalf("expected host='/host/dst' container='/container/src', got host=%q container=%q", gotHostPath, gotContainerPath)
+	}
+	if result.BytesCopied != 7 {
+		t.Fatalf("expected BytesCopied=7, got %d", result.BytesCopied)
+	}
+}
+
+// TestHandleCopyRequest_RoundTrip exercises the RPC-facing entry point for
+// a push followed by a pull, checking that each reaches the wrapper with the
+// path of its source end: the host path for the push, the sanitized
+// container path for the pull.
+func TestHandleCopyRequest_RoundTrip(t *testing.T) {
+	ww := newFakeWorkloadWrapper()
+	var stored string
+	ww.copyFn = func(workloadName, hostPath, containerPath string, dir CopyDirection, overwrite, preserveOwnership, noDereference bool, progress func(int64, int64)) (CopyResult, error) {
+		if dir == CopyDirectionToWorkload {
+			stored = hostPath
+		} else {
+			stored = containerPath
+		}
+		if progress != nil {
+			progress(1, 1)
+		}
+		return CopyResult{BytesCopied: 1}, nil
+	}
+
+	w, err := NewWorkloadManagerWithParams(t.TempDir(), ww, "device-config", "/tmp/device-config", nil)
+	if err != nil {
+		t.Fatalf("NewWorkloadManagerWithParams: %v", err)
+	}
+
+	if _, err := w.HandleCopyRequest(CopyRequest{
+		WorkloadName:  "workload",
+		Direction:     CopyDirectionToWorkload,
+		HostPath:      "/host/config.yaml",
+		ContainerPath: "etc/config.yaml",
+	}); err != nil {
+		t.Fatalf("push: %v", err)
+	}
+	if stored != "/host/config.yaml" {
+		t.Fatalf("expected push to record host path, got %q", stored)
+	}
+
+	if _, err := w.HandleCopyRequest(CopyRequest{
+		WorkloadName:  "workload",
+		Direction:     CopyDirectionFromWorkload,
+		HostPath:      "/host/out.yaml",
+		ContainerPath: "etc/config.yaml",
+	}); err != nil {
+		t.Fatalf("pull: %v", err)
+	}
+	if stored != "/etc/config.yaml" {
+		t.Fatalf("expected pull to record sanitized container path, got %q", stored)
+	}
+}
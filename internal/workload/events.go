@@ -0,0 +1,115 @@
+package workload
+
+import (
+	"context"
+	"time"
+
+	"git.sr.ht/~spc/go-log"
+)
+
+// eventTickerFallback is how often the manager polls manifests against
+// running workloads, whether or not the event stream is up. It's a slow
+// safety net, not the primary reconciliation path.
+const eventTickerFallback = 5 * time.Minute
+
+// eventDebounceWindow coalesces bursts of events (a container dying and its
+// pod's other containers following suit) into a single reconciliation pass.
+const eventDebounceWindow = 500 * time.Millisecond
+
+// relevantEventStatuses are the WorkloadEvent.Status values that should
+// trigger reconciliation; anything else observed on the stream is ignored.
+var relevantEventStatuses = map[string]struct{}{
+	"died":          {},
+	"stopped":       {},
+	"oom":           {},
+	"health_status": {},
+}
+
+// WorkloadEvent is a single container lifecycle event surfaced by
+// WorkloadWrapper.Events, modeled after `podman events --format json`.
+type WorkloadEvent struct {
+	WorkloadName string
+	Status       string
+}
+
+// watchWorkloadEvents subscribes to the podman event stream and triggers a
+// debounced reconciliation on every relevant event. It reconnects with
+// backoff if the stream breaks (e.g. across a podman restart) and exits as
+// soon as ctx is cancelled, so it never outlives the manager or blocks
+// Deregister.
+func (w *WorkloadManager) watchWorkloadEvents(ctx context.Context) {
+	backoff := time.Second
+	const maxBackoff = 30 * time.Second
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		events, err := w.workloads.Events(ctx)
+		if err != nil {
+			log.Errorf("cannot subscribe to workload events: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff < maxBackoff {
+				backoff *= 2
+			}
+			continue
+		}
+		backoff = time.Second
+
+		if !w.consumeWorkloadEvents(ctx, events) {
+			return
+		}
+	}
+}
+
+// consumeWorkloadEvents drains events, debouncing relevant ones into a
+// single reconciliation call, until the channel closes (signalling the
+// stream needs to be re-established) or ctx is cancelled. It returns false
+// when the manager should stop watching altogether.
+func (w *WorkloadManager) consumeWorkloadEvents(ctx context.Context, events <-chan WorkloadEvent) bool {
+	var debounce *time.Timer
+	var debounceC <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			if debounce != nil {
+				debounce.Stop()
+			}
+			return false
+		case event, ok := <-events:
+			if !ok {
+				if debounce != nil {
+					debounce.Stop()
+				}
+				return true
+			}
+			if _, relevant := relevantEventStatuses[event.Status]; !relevant {
+				continue
+			}
+			log.Tracef("workload event: %s/%s", event.WorkloadName, event.Status)
+			if debounce == nil {
+				debounce = time.NewTimer(eventDebounceWindow)
+				debounceC = debounce.C
+			} else {
+				if !debounce.Stop() {
+					<-debounce.C
+				}
+				debounce.Reset(eventDebounceWindow)
+			}
+		case <-debounceC:
+			debounce = nil
+			debounceC = nil
+			if err := w.ensureWorkloadsFromManifestsAreRunning(); err != nil {
+				log.Error(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package workload
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestConsumeWorkloadEvents_DebouncesBurstIntoOneReconciliation verifies a
+// burst of relevant events (e.g. every container in a pod dying together)
+// triggers a single reconciliation pass, not one per event.
+func TestConsumeWorkloadEvents_DebouncesBurstIntoOneReconciliation(t *testing.T) {
+	ww := newFakeWorkloadWrapper()
+	w := newTestManager(t)
+	w.workloads = ww
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan WorkloadEvent)
+	done := make(chan struct{})
+	go func() {
+		w.consumeWorkloadEvents(ctx, events)
+		close(done)
+	}()
+
+	for i := 0; i < 5; i++ {
+		events <- WorkloadEvent{WorkloadName: "workload", Status: "died"}
+		time.Sleep(eventDebounceWindow / 4)
+	}
+
+	time.Sleep(2 * eventDebounceWindow)
+	cancel()
+	<-done
+
+	if ww.listCalls != 1 {
+		t.Fatalf("expected exactly 1 reconciliation pass for a debounced burst, got %d", ww.listCalls)
+	}
+}
+
+// TestConsumeWorkloadEvents_IgnoresIrrelevantStatuses verifies statuses
+// outside relevantEventStatuses never trigger a reconciliation pass.
+func TestConsumeWorkloadEvents_IgnoresIrrelevantStatuses(t *testing.T) {
+	ww := newFakeWorkloadWrapper()
+	w := newTestManager(t)
+	w.workloads = ww
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan WorkloadEvent)
+	done := make(chan struct{})
+	go func() {
+		w.consumeWorkloadEvents(ctx, events)
+		close(done)
+	}()
+
+	events <- WorkloadEvent{WorkloadName: "workload", Status: "created"}
+	time.Sleep(2 * eventDebounceWindow)
+	cancel()
+	<-done
+
+	if ww.listCalls != 0 {
+		t.Fatalf("expected an irrelevant status to never trigger reconciliation, got %d calls", ww.listCalls)
+	}
+}
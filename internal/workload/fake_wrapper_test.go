@@ -0,0 +1,78 @@
+package workload
+
+import (
+	"context"
+
+	api2 "github.com/jakub-dzon/k4e-device-worker/internal/workload/api"
+	v1 "k8s.io/api/core/v1"
+)
+
+// fakeWorkloadWrapper is a minimal, in-memory WorkloadWrapper stand-in used
+// across this package's tests. Every field is optional: tests only set the
+// hooks the behavior under test actually exercises.
+type fakeWorkloadWrapper struct {
+	workloads map[string]*v1.Pod
+
+	copyFn        func(workloadName, hostPath, containerPath string, dir CopyDirection, overwrite, preserveOwnership, noDereference bool, progress func(int64, int64)) (CopyResult, error)
+	healthCheckFn func(workloadName, containerName string) (bool, string, error)
+
+	listCalls int
+}
+
+func newFakeWorkloadWrapper() *fakeWorkloadWrapper {
+	return &fakeWorkloadWrapper{workloads: make(map[string]*v1.Pod)}
+}
+
+func (f *fakeWorkloadWrapper) Init() error { return nil }
+
+func (f *fakeWorkloadWrapper) List() ([]api2.WorkloadInfo, error) {
+	f.listCalls++
+	var infos []api2.WorkloadInfo
+	for name := range f.workloads {
+		infos = append(infos, api2.WorkloadInfo{Name: name, Status: "Running"})
+	}
+	return infos, nil
+}
+
+func (f *fakeWorkloadWrapper) Run(pod *v1.Pod, manifestPath string, configMapsPaths []string) error {
+	f.workloads[pod.Name] = pod
+	return nil
+}
+
+func (f *fakeWorkloadWrapper) Start(pod *v1.Pod) error {
+	f.workloads[pod.Name] = pod
+	return nil
+}
+
+func (f *fakeWorkloadWrapper) Remove(workloadName string) error {
+	delete(f.workloads, workloadName)
+	return nil
+}
+
+func (f *fakeWorkloadWrapper) RemoveTable() error                 { return nil }
+func (f *fakeWorkloadWrapper) RemoveMappingFile() error           { return nil }
+func (f *fakeWorkloadWrapper) PersistConfiguration() error        { return nil }
+func (f *fakeWorkloadWrapper) RegisterObserver(observer Observer) {}
+
+func (f *fakeWorkloadWrapper) HealthCheck(workloadName, containerName string) (bool, string, error) {
+	if f.healthCheckFn != nil {
+		return f.healthCheckFn(workloadName, containerName)
+	}
+	return true, "", nil
+}
+
+func (f *fakeWorkloadWrapper) Events(ctx context.Context) (<-chan WorkloadEvent, error) {
+	events := make(chan WorkloadEvent)
+	go func() {
+		<-ctx.Done()
+		close(events)
+	}()
+	return events, nil
+}
+
+func (f *fakeWorkloadWrapper) Copy(workloadName, hostPath, containerPath string, dir CopyDirection, overwrite, preserveOwnership, noDereference bool, progress func(int64, int64)) (CopyResult, error) {
+	if f.copyFn != nil {
+		return f.copyFn(workloadName, hostPath, containerPath, dir, overwrite, preserveOwnership, noDereference, progress)
+	}
+	return CopyResult{}, nil
+}
@@ -0,0 +1,273 @@
+package workload
+
+import (
+	"context"
+	"time"
+
+	"git.sr.ht/~spc/go-log"
+	api2 "github.com/jakub-dzon/k4e-device-worker/internal/workload/api"
+	v1 "k8s.io/api/core/v1"
+)
+
+// defaultHealthCheckPeriod is used when a probe doesn't set PeriodSeconds,
+// mirroring podman's default healthcheck interval.
+const defaultHealthCheckPeriod = 30 * time.Second
+
+// healthCheckActionAnnotationPrefix namespaces the per-container failure
+// action, since v1.Probe (unlike libpod's healthcheck) has no concept of
+// what to do once a container has exhausted its retries. The command,
+// interval, timeout, start period and retry count are carried on the
+// container's own LivenessProbe (Exec, PeriodSeconds, TimeoutSeconds,
+// InitialDelaySeconds, FailureThreshold) and round-trip through the stored
+// pod manifest like the rest of the spec.
+const healthCheckActionAnnotationPrefix = "healthcheck.flotta.io/action."
+
+// healthCheckAction is the action taken once a container's healthcheck has
+// failed as many times in a row as its LivenessProbe's FailureThreshold.
+type healthCheckAction string
+
+const (
+	healthCheckActionNone    healthCheckAction = "none"
+	healthCheckActionRestart healthCheckAction = "restart"
+	healthCheckActionStop    healthCheckAction = "stop"
+
+	defaultHealthCheckAction = healthCheckActionNone
+)
+
+// healthCheckKey identifies a single container's healthcheck state. Pods
+// are multi-container, and a `podman healthcheck run` failure on one
+// container must not be blamed on, or double-counted against, another.
+type healthCheckKey struct {
+	workloadName  string
+	containerName string
+}
+
+// healthCheckState is the in-memory view of a single container's health,
+// tracked by the monitor goroutine and surfaced via api2.WorkloadInfo.
+type healthCheckState struct {
+	healthy         bool
+	consecutiveFail int
+	lastLog         string
+}
+
+func healthCheckActionAnnotationKey(containerName string) string {
+	return healthCheckActionAnnotationPrefix + containerName
+}
+
+// healthCheckActionFor returns the configured failure action for a
+// container, defaulting to "none" when the workload didn't request one.
+func healthCheckActionFor(pod *v1.Pod, containerName string) healthCheckAction {
+	action := healthCheckAction(pod.Annotations[healthCheckActionAnnotationKey(containerName)])
+	switch action {
+	case healthCheckActionRestart, healthCheckActionStop:
+		return action
+	default:
+		return defaultHealthCheckAction
+	}
+}
+
+// runHealthChecks reconciles the set of running per-container healthcheck
+// goroutines against manifestNameToPodAndPath: starting one for every
+// container with a LivenessProbe.Exec that doesn't have one yet, refreshing
+// the pod each already-running one checks against, and stopping any whose
+// container or workload is no longer present. Each goroutine drives itself
+// off the container's own probe.PeriodSeconds instead of this reconcile
+// cadence, so a hung-but-not-dead container is re-checked at the interval
+// the workload actually asked for, not just when the event stream or the
+// fallback ticker happens to fire.
+func (w *WorkloadManager) runHealthChecks(manifestNameToPodAndPath map[string]podAndPath) {
+	wanted := make(map[healthCheckKey]struct{})
+	for name, podWithPath := range manifestNameToPodAndPath {
+		pod := podWithPath.pod
+		for _, container := range pod.Spec.Containers {
+			probe := container.LivenessProbe
+			if probe == nil || probe.Exec == nil {
+				continue
+			}
+			key := healthCheckKey{workloadName: name, containerName: container.Name}
+			wanted[key] = struct{}{}
+			w.ensureHealthCheckTimer(key, &pod)
+		}
+	}
+
+	w.healthTimersLock.Lock()
+	for key, cancel := range w.healthCheckTimers {
+		if _, ok := wanted[key]; !ok {
+			cancel()
+			delete(w.healthCheckTimers, key)
+			delete(w.healthCheckPods, key)
+			w.healthLock.Lock()
+			delete(w.healthState, key)
+			w.healthLock.Unlock()
+		}
+	}
+	w.healthTimersLock.Unlock()
+}
+
+// ensureHealthCheckTimer refreshes key's pod (so a redeployed workload's
+// updated probe settings take effect on the next tick) and, if key doesn't
+// already have a goroutine driving its healthcheck, starts one.
+func (w *WorkloadManager) ensureHealthCheckTimer(key healthCheckKey, pod *v1.Pod) {
+	w.healthTimersLock.Lock()
+	defer w.healthTimersLock.Unlock()
+
+	w.healthCheckPods[key] = pod
+	if _, running := w.healthCheckTimers[key]; running {
+		return
+	}
+	ctx, cancel := context.WithCancel(w.lifecycleCtx)
+	w.healthCheckTimers[key] = cancel
+	go w.runContainerHealthCheckLoop(ctx, key)
+}
+
+// healthCheckTarget looks up key's current pod and probe, as last refreshed
+// by ensureHealthCheckTimer. It returns ok=false once the container or
+// workload has been reconciled away.
+func (w *WorkloadManager) healthCheckTarget(key healthCheckKey) (pod *v1.Pod, probe *v1.Probe, ok bool) {
+	w.healthTimersLock.Lock()
+	pod, ok = w.healthCheckPods[key]
+	w.healthTimersLock.Unlock()
+	if !ok {
+		return nil, nil, false
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name == key.containerName && container.LivenessProbe != nil {
+			return pod, container.LivenessProbe, true
+		}
+	}
+	return nil, nil, false
+}
+
+// runContainerHealthCheckLoop drives key's healthcheck at its own probe's
+// cadence until ctx is cancelled (the container is reconciled away, or the
+// manager is deregistered). It waits out InitialDelaySeconds before the
+// first check, so a slow-starting container isn't tripped to unhealthy
+// while it's still initializing.
+func (w *WorkloadManager) runContainerHealthCheckLoop(ctx context.Context, key healthCheckKey) {
+	_, probe, ok := w.healthCheckTarget(key)
+	if !ok {
+		return
+	}
+	if delay := time.Duration(probe.InitialDelaySeconds) * time.Second; delay > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+
+	for {
+		pod, probe, ok := w.healthCheckTarget(key)
+		if !ok {
+			return
+		}
+		w.runContainerHealthCheck(key.workloadName, key.containerName, pod, probe)
+
+		period := time.Duration(probe.PeriodSeconds) * time.Second
+		if period <= 0 {
+			period = defaultHealthCheckPeriod
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(period):
+		}
+	}
+}
+
+func (w *WorkloadManager) runContainerHealthCheck(workloadName, containerName string, pod *v1.Pod, probe *v1.Probe) {
+	healthy, healthLog, err := w.workloads.HealthCheck(workloadName, containerName)
+	if err != nil {
+		log.Errorf("healthcheck failed for workload '%s' container '%s': %v", workloadName, containerName, err)
+	}
+
+	retries := int(probe.FailureThreshold)
+	if retries <= 0 {
+		retries = 1
+	}
+
+	key := healthCheckKey{workloadName: workloadName, containerName: containerName}
+
+	w.healthLock.Lock()
+	state, ok := w.healthState[key]
+	if !ok {
+		state = &healthCheckState{healthy: true}
+		w.healthState[key] = state
+	}
+	previouslyHealthy := state.healthy
+	state.lastLog = healthLog
+	if healthy {
+		state.consecutiveFail = 0
+		state.healthy = true
+	} else {
+		state.consecutiveFail++
+		if state.consecutiveFail >= retries {
+			state.healthy = false
+		}
+	}
+	becameUnhealthy := previouslyHealthy && !state.healthy
+	becameHealthy := !previouslyHealthy && state.healthy
+	w.healthLock.Unlock()
+
+	if becameHealthy {
+		w.notifyHealthObservers(workloadName, containerName, true, healthLog)
+		return
+	}
+	if !becameUnhealthy {
+		return
+	}
+
+	action := healthCheckActionFor(pod, containerName)
+	log.Infof("workload '%s' container '%s' is unhealthy after %d failed healthchecks, applying action '%s'", workloadName, containerName, retries, action)
+	w.notifyHealthObservers(workloadName, containerName, false, healthLog)
+
+	switch action {
+	case healthCheckActionRestart:
+		if err := w.workloads.Remove(workloadName); err != nil {
+			log.Errorf("cannot remove unhealthy workload '%s': %v", workloadName, err)
+			return
+		}
+		manifestPath := w.getManifestPath(workloadName)
+		if err := w.workloads.Run(pod, manifestPath, w.prepareConfigMapsPaths()); err != nil {
+			log.Errorf("cannot restart unhealthy workload '%s': %v", workloadName, err)
+		}
+	case healthCheckActionStop:
+		if err := w.workloads.Remove(workloadName); err != nil {
+			log.Errorf("cannot stop unhealthy workload '%s': %v", workloadName, err)
+		}
+	case healthCheckActionNone:
+		// Nothing to do beyond the observer notification above and what's
+		// surfaced through api2.WorkloadInfo.
+	}
+}
+
+// healthForWorkload returns the per-container health snapshot for name, for
+// merging into the api2.WorkloadInfo ListWorkloads returns.
+func (w *WorkloadManager) healthForWorkload(name string) map[string]api2.ContainerHealth {
+	w.healthLock.Lock()
+	defer w.healthLock.Unlock()
+
+	var health map[string]api2.ContainerHealth
+	for key, state := range w.healthState {
+		if key.workloadName != name {
+			continue
+		}
+		if health == nil {
+			health = make(map[string]api2.ContainerHealth)
+		}
+		health[key.containerName] = api2.ContainerHealth{Healthy: state.healthy, Log: state.lastLog}
+	}
+	return health
+}
+
+// notifyHealthObservers fans a container health transition out to every
+// observer registered through WorkloadManager.RegisterObserver.
+func (w *WorkloadManager) notifyHealthObservers(workloadName, containerName string, healthy bool, healthLog string) {
+	w.observersLock.Lock()
+	observers := append([]Observer(nil), w.observers...)
+	w.observersLock.Unlock()
+
+	for _, observer := range observers {
+		observer.OnWorkloadHealthChanged(workloadName, containerName, healthy, healthLog)
+	}
+}
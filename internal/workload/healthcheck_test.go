@@ -0,0 +1,186 @@
+package workload
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func probeWithRetries(retries int32) *v1.Probe {
+	return &v1.Probe{
+		Handler:          v1.Handler{Exec: &v1.ExecAction{Command: []string{"true"}}},
+		FailureThreshold: retries,
+	}
+}
+
+// TestRunContainerHealthCheck_TripsAtConfiguredRetries verifies a container
+// is only marked unhealthy once it has failed as many times in a row as its
+// own FailureThreshold, not after the first failure.
+func TestRunContainerHealthCheck_TripsAtConfiguredRetries(t *testing.T) {
+	ww := newFakeWorkloadWrapper()
+	healthy := false
+	ww.healthCheckFn = func(workloadName, containerName string) (bool, string, error) {
+		return healthy, "boom", nil
+	}
+
+	w, err := NewWorkloadManagerWithParams(t.TempDir(), ww, "device-config", "/tmp/device-config", nil)
+	if err != nil {
+		t.Fatalf("NewWorkloadManagerWithParams: %v", err)
+	}
+
+	pod := &v1.Pod{}
+	pod.Name = "workload"
+	probe := probeWithRetries(3)
+
+	w.runContainerHealthCheck("workload", "container", pod, probe)
+	if !w.healthState[healthCheckKey{"workload", "container"}].healthy {
+		t.Fatalf("expected still healthy after 1st failure (retries=3)")
+	}
+	w.runContainerHealthCheck("workload", "container", pod, probe)
+	if !w.healthState[healthCheckKey{"workload", "container"}].healthy {
+		t.Fatalf("expected still healthy after 2nd failure (retries=3)")
+	}
+	w.runContainerHealthCheck("workload", "container", pod, probe)
+	if w.healthState[healthCheckKey{"workload", "container"}].healthy {
+		t.Fatalf("expected unhealthy after 3rd consecutive failure (retries=3)")
+	}
+}
+
+// TestRunContainerHealthCheck_PerContainerState verifies one container's
+// failures don't get folded into another container's counter.
+func TestRunContainerHealthCheck_PerContainerState(t *testing.T) {
+	ww := newFakeWorkloadWrapper()
+	ww.healthCheckFn = func(workloadName, containerName string) (bool, string, error) {
+		return containerName != "bad", "", nil
+	}
+
+	w, err := NewWorkloadManagerWithParams(t.TempDir(), ww, "device-config", "/tmp/device-config", nil)
+	if err != nil {
+		t.Fatalf("NewWorkloadManagerWithParams: %v", err)
+	}
+
+	pod := &v1.Pod{}
+	pod.Name = "workload"
+	probe := probeWithRetries(1)
+
+	w.runContainerHealthCheck("workload", "good", pod, probe)
+	w.runContainerHealthCheck("workload", "bad", pod, probe)
+
+	if !w.healthState[healthCheckKey{"workload", "good"}].healthy {
+		t.Fatalf("expected 'good' container to remain healthy regardless of 'bad' container's failures")
+	}
+	if w.healthState[healthCheckKey{"workload", "bad"}].healthy {
+		t.Fatalf("expected 'bad' container to be unhealthy")
+	}
+}
+
+// TestNotifyHealthObservers_FiresOnTransition verifies an observer is
+// notified when a container crosses its retry threshold.
+func TestNotifyHealthObservers_FiresOnTransition(t *testing.T) {
+	ww := newFakeWorkloadWrapper()
+	ww.healthCheckFn = func(workloadName, containerName string) (bool, string, error) {
+		return false, "boom", nil
+	}
+
+	w, err := NewWorkloadManagerWithParams(t.TempDir(), ww, "device-config", "/tmp/device-config", nil)
+	if err != nil {
+		t.Fatalf("NewWorkloadManagerWithParams: %v", err)
+	}
+
+	var notified bool
+	w.RegisterObserver(observerFunc(func(workloadName, containerName string, healthy bool, log string) {
+		notified = true
+		if workloadName != "workload" || containerName != "container" || healthy {
+			t.Fatalf("unexpected notification: %s/%s healthy=%v", workloadName, containerName, healthy)
+		}
+	}))
+
+	pod := &v1.Pod{}
+	pod.Name = "workload"
+	probe := probeWithRetries(1)
+	w.runContainerHealthCheck("workload", "container", pod, probe)
+
+	if !notified {
+		t.Fatalf("expected observer to be notified of the health transition")
+	}
+}
+
+// TestRunHealthChecks_SuppressesCountingDuringInitialDelay verifies a
+// container with InitialDelaySeconds set isn't probed at all until that
+// delay has elapsed, so a slow-starting container can't be tripped to
+// unhealthy while it's still initializing.
+func TestRunHealthChecks_SuppressesCountingDuringInitialDelay(t *testing.T) {
+	ww := newFakeWorkloadWrapper()
+	var lock sync.Mutex
+	calls := 0
+	ww.healthCheckFn = func(workloadName, containerName string) (bool, string, error) {
+		lock.Lock()
+		calls++
+		lock.Unlock()
+		return true, "", nil
+	}
+
+	w, err := NewWorkloadManagerWithParams(t.TempDir(), ww, "device-config", "/tmp/device-config", nil)
+	if err != nil {
+		t.Fatalf("NewWorkloadManagerWithParams: %v", err)
+	}
+
+	pod := v1.Pod{}
+	pod.Name = "workload"
+	probe := probeWithRetries(1)
+	probe.InitialDelaySeconds = 1
+	pod.Spec.Containers = []v1.Container{{Name: "container", LivenessProbe: probe}}
+
+	w.runHealthChecks(map[string]podAndPath{"workload": {pod: pod}})
+
+	time.Sleep(300 * time.Millisecond)
+	lock.Lock()
+	got := calls
+	lock.Unlock()
+	if got != 0 {
+		t.Fatalf("expected no healthchecks before InitialDelaySeconds elapses, got %d", got)
+	}
+}
+
+// TestRunHealthChecks_StopsTimerForRemovedContainer verifies a container
+// dropped from manifestNameToPodAndPath (workload undeployed or redeployed
+// without it) stops being probed.
+func TestRunHealthChecks_StopsTimerForRemovedContainer(t *testing.T) {
+	ww := newFakeWorkloadWrapper()
+	w, err := NewWorkloadManagerWithParams(t.TempDir(), ww, "device-config", "/tmp/device-config", nil)
+	if err != nil {
+		t.Fatalf("NewWorkloadManagerWithParams: %v", err)
+	}
+
+	pod := v1.Pod{}
+	pod.Name = "workload"
+	probe := probeWithRetries(1)
+	pod.Spec.Containers = []v1.Container{{Name: "container", LivenessProbe: probe}}
+	key := healthCheckKey{"workload", "container"}
+
+	w.runHealthChecks(map[string]podAndPath{"workload": {pod: pod}})
+	w.healthTimersLock.Lock()
+	_, running := w.healthCheckTimers[key]
+	w.healthTimersLock.Unlock()
+	if !running {
+		t.Fatalf("expected a healthcheck timer to be started for %+v", key)
+	}
+
+	w.runHealthChecks(map[string]podAndPath{})
+	w.healthTimersLock.Lock()
+	_, stillRunning := w.healthCheckTimers[key]
+	_, podStillTracked := w.healthCheckPods[key]
+	w.healthTimersLock.Unlock()
+	if stillRunning || podStillTracked {
+		t.Fatalf("expected the timer for %+v to be stopped once its container is gone", key)
+	}
+}
+
+// observerFunc adapts a function to the Observer interface for tests.
+type observerFunc func(workloadName, containerName string, healthy bool, log string)
+
+func (f observerFunc) OnWorkloadHealthChanged(workloadName, containerName string, healthy bool, log string) {
+	f(workloadName, containerName, healthy, log)
+}
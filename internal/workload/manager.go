@@ -2,15 +2,18 @@ package workload
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/jakub-dzon/k4e-device-worker/internal/metrics"
 	"github.com/jakub-dzon/k4e-device-worker/internal/volumes"
 
 	"git.sr.ht/~spc/go-log"
@@ -21,7 +24,12 @@ import (
 )
 
 const (
-	defaultWorkloadsMonitoringInterval = 15
+	// defaultWorkloadsMonitoringInterval is the ticker's fallback cadence now
+	// that reconciliation is primarily event-driven (see watchWorkloadEvents).
+	// It only has to catch what the event stream misses, so it can be much
+	// slower than before - operators on battery-constrained edge devices can
+	// raise it further via DeviceConfigurationMessage.WorkloadsMonitoringInterval.
+	defaultWorkloadsMonitoringInterval = int64(eventTickerFallback / time.Second)
 )
 
 type WorkloadManager struct {
@@ -33,6 +41,19 @@ type WorkloadManager struct {
 	deregistered        bool
 	deviceConfigMapName string
 	deviceConfigMapPath string
+	healthLock          sync.Mutex
+	healthState         map[healthCheckKey]*healthCheckState
+	healthTimersLock    sync.Mutex
+	healthCheckTimers   map[healthCheckKey]context.CancelFunc
+	healthCheckPods     map[healthCheckKey]*v1.Pod
+	observersLock       sync.Mutex
+	observers           []Observer
+	lifecycleCtx        context.Context
+	lifecycleCancel     context.CancelFunc
+	metricsScraper      *metrics.Scraper
+	volumePruneInterval time.Duration
+	volumePruneMaxAge   time.Duration
+	lastVolumePrune     time.Time
 }
 
 type podAndPath struct {
@@ -40,16 +61,21 @@ type podAndPath struct {
 	manifestPath string
 }
 
-func NewWorkloadManager(dataDir string, deviceConfigMapName string, deviceConfigMapPath string) (*WorkloadManager, error) {
+func NewWorkloadManager(dataDir string, deviceConfigMapName string, deviceConfigMapPath string, metricsDispatcher metrics.Dispatcher) (*WorkloadManager, error) {
 	wrapper, err := newWorkloadInstance(dataDir)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewWorkloadManagerWithParams(dataDir, wrapper, deviceConfigMapName, deviceConfigMapPath)
+	return NewWorkloadManagerWithParams(dataDir, wrapper, deviceConfigMapName, deviceConfigMapPath, metricsDispatcher)
 }
 
-func NewWorkloadManagerWithParams(dataDir string, ww WorkloadWrapper, deviceConfigMapName string, deviceConfigMapPath string) (*WorkloadManager, error) {
+// NewWorkloadManagerWithParams wires up a WorkloadManager around an
+// already-constructed WorkloadWrapper (e.g. for tests) and, when
+// metricsDispatcher is non-nil, its Prometheus scrape subsystem - callers
+// that don't need metrics scraping can pass nil and Update will simply
+// leave SystemMetricsConfiguration unapplied.
+func NewWorkloadManagerWithParams(dataDir string, ww WorkloadWrapper, deviceConfigMapName string, deviceConfigMapPath string, metricsDispatcher metrics.Dispatcher) (*WorkloadManager, error) {
 	manifestsDir := path.Join(dataDir, "manifests")
 	if err := os.MkdirAll(manifestsDir, 0755); err != nil {
 		return nil, fmt.Errorf("cannot create directory: %w", err)
@@ -59,6 +85,7 @@ func NewWorkloadManagerWithParams(dataDir string, ww WorkloadWrapper, deviceConf
 		return nil, fmt.Errorf("cannot create directory: %w", err)
 	}
 
+	lifecycleCtx, lifecycleCancel := context.WithCancel(context.Background())
 	manager := WorkloadManager{
 		manifestsDir:        manifestsDir,
 		volumesDir:          volumesDir,
@@ -67,17 +94,33 @@ func NewWorkloadManagerWithParams(dataDir string, ww WorkloadWrapper, deviceConf
 		deregistered:        false,
 		deviceConfigMapName: deviceConfigMapName,
 		deviceConfigMapPath: deviceConfigMapPath,
+		healthState:         make(map[healthCheckKey]*healthCheckState),
+		healthCheckTimers:   make(map[healthCheckKey]context.CancelFunc),
+		healthCheckPods:     make(map[healthCheckKey]*v1.Pod),
+		lifecycleCtx:        lifecycleCtx,
+		lifecycleCancel:     lifecycleCancel,
 	}
 	if err := manager.workloads.Init(); err != nil {
 		return nil, err
 	}
+	if metricsDispatcher != nil {
+		manager.RegisterMetricsDispatcher(metricsDispatcher)
+	}
 
 	manager.initTicker(defaultWorkloadsMonitoringInterval)
+	go manager.watchWorkloadEvents(lifecycleCtx)
 	return &manager, nil
 }
 
 func (w *WorkloadManager) ListWorkloads() ([]api2.WorkloadInfo, error) {
-	return w.workloads.List()
+	workloads, err := w.workloads.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range workloads {
+		workloads[i].Health = w.healthForWorkload(workloads[i].Name)
+	}
+	return workloads, nil
 }
 
 func (w *WorkloadManager) GetExportedHostPath(workloadName string) string {
@@ -95,6 +138,7 @@ func (w *WorkloadManager) Update(configuration models.DeviceConfigurationMessage
 
 	configMapsPaths := w.prepareConfigMapsPaths()
 	configuredWorkloadNameSet := make(map[string]struct{})
+	var metricsTargets []metrics.Target
 	for _, workload := range configuration.Workloads {
 		log.Tracef("Deploying workload: %s", workload.Name)
 		configuredWorkloadNameSet[workload.Name] = struct{}{}
@@ -105,6 +149,9 @@ func (w *WorkloadManager) Update(configuration models.DeviceConfigurationMessage
 				"cannot convert workload '%s' to pod: %s", workload.Name, err))
 			continue
 		}
+		if target, ok := scrapeTargetFromPod(pod); ok {
+			metricsTargets = append(metricsTargets, target)
+		}
 		manifestPath := w.getManifestPath(pod.Name)
 		podYaml, err := w.toPodYaml(pod)
 		if err != nil {
@@ -164,8 +211,29 @@ func (w *WorkloadManager) Update(configuration models.DeviceConfigurationMessage
 	}
 	// Reset the interval of the current monitoring routine
 	if configuration.WorkloadsMonitoringInterval > 0 {
-		w.ticker.Reset(time.Duration(configuration.WorkloadsMonitoringInterval))
-	}
+		w.ticker.Reset(time.Second * time.Duration(configuration.WorkloadsMonitoringInterval))
+	}
+	if w.metricsScraper != nil {
+		// Reconfigure on every Update, even when the operator cleared the
+		// Metrics block entirely - otherwise a scraper that was already
+		// running keeps scraping its last-known targets/allow-list forever,
+		// and there'd be no way to turn it back off.
+		metricsConfig := models.SystemMetricsConfiguration{Disabled: true}
+		if configuration.Configuration != nil && configuration.Configuration.Metrics != nil {
+			metricsConfig = *configuration.Configuration.Metrics
+		}
+		w.metricsScraper.Configure(metricsConfig, metricsTargets)
+	}
+	// Same reasoning for volume pruning: clearing the Storage block should
+	// turn pruning back off, not leave the previous interval/max-age in
+	// effect.
+	var volumePruneIntervalMinutes, volumePruneMaxUnusedHours int64
+	if configuration.Configuration != nil && configuration.Configuration.Storage != nil {
+		volumePruneIntervalMinutes = configuration.Configuration.Storage.VolumesPruneIntervalMinutes
+		volumePruneMaxUnusedHours = configuration.Configuration.Storage.VolumesPruneMaxUnusedHours
+	}
+	w.volumePruneInterval = time.Duration(volumePruneIntervalMinutes) * time.Minute
+	w.volumePruneMaxAge = time.Duration(volumePruneMaxUnusedHours) * time.Hour
 	return errors
 }
 
@@ -259,9 +327,28 @@ func (w *WorkloadManager) ensureWorkloadsFromManifestsAreRunning() error {
 	if err = w.workloads.PersistConfiguration(); err != nil {
 		log.Errorf("failed to persist workload configuration: %v", err)
 	}
+	w.runHealthChecks(manifestNameToPodAndPath)
+	w.pruneVolumesIfDue()
 	return nil
 }
 
+// pruneVolumesIfDue runs PruneVolumes on the configured cadence
+// (DeviceConfigurationMessage.Configuration.Storage.VolumesPruneIntervalMinutes).
+// A zero interval disables periodic pruning - operators can still call
+// PruneVolumes directly. Caller must hold managementLock.
+func (w *WorkloadManager) pruneVolumesIfDue() {
+	if w.volumePruneInterval <= 0 {
+		return
+	}
+	if time.Since(w.lastVolumePrune) < w.volumePruneInterval {
+		return
+	}
+	w.lastVolumePrune = time.Now()
+	if _, err := w.pruneVolumesLocked(PruneFilter{MaxUnusedAge: w.volumePruneMaxAge}); err != nil {
+		log.Errorf("failed to prune unused volumes: %v", err)
+	}
+}
+
 func (w *WorkloadManager) indexWorkloads() (map[string]api2.WorkloadInfo, error) {
 	workloads, err := w.workloads.List()
 	if err != nil {
@@ -275,9 +362,21 @@ func (w *WorkloadManager) indexWorkloads() (map[string]api2.WorkloadInfo, error)
 }
 
 func (w *WorkloadManager) RegisterObserver(observer Observer) {
+	w.observersLock.Lock()
+	w.observers = append(w.observers, observer)
+	w.observersLock.Unlock()
 	w.workloads.RegisterObserver(observer)
 }
 
+// RegisterMetricsDispatcher wires up the Prometheus scrape subsystem,
+// forwarding allow-listed samples through dispatcher (the same
+// heartbeat/dispatcher channel status updates go out on). Until this is
+// called, SystemMetricsConfiguration is accepted by Update but has no
+// effect.
+func (w *WorkloadManager) RegisterMetricsDispatcher(dispatcher metrics.Dispatcher) {
+	w.metricsScraper = metrics.NewScraper(dispatcher)
+}
+
 func (w *WorkloadManager) Deregister() error {
 	w.managementLock.Lock()
 	defer w.managementLock.Unlock()
@@ -319,6 +418,10 @@ func (w *WorkloadManager) Deregister() error {
 		log.Errorf("failed to remove mapping file: %v", err)
 	}
 
+	if w.metricsScraper != nil {
+		w.metricsScraper.Stop()
+	}
+
 	w.deregistered = true
 	return errors
 }
@@ -328,6 +431,13 @@ func (w *WorkloadManager) removeTicker() error {
 	if w.ticker != nil {
 		w.ticker.Stop()
 	}
+	// Cancel the event-stream watcher too. This is a plain context
+	// cancellation rather than anything under managementLock, since
+	// watchWorkloadEvents can itself call back into ensureWorkloadsFromManifestsAreRunning,
+	// which acquires managementLock - and Deregister is already holding it here.
+	if w.lifecycleCancel != nil {
+		w.lifecycleCancel()
+	}
 	return nil
 }
 
@@ -421,9 +531,58 @@ func (w *WorkloadManager) toPod(workload *models.Workload) (*v1.Pod, error) {
 		containers = append(containers, container)
 	}
 	pod.Spec.Containers = containers
+	setPrometheusScrapeAnnotations(&pod)
+	if err := w.materializeNamedVolumes(&pod); err != nil {
+		return nil, err
+	}
 	return &pod, nil
 }
 
+// setPrometheusScrapeAnnotations marks the pod as a Prometheus scrape target
+// when one of its containers exposes a port named "metrics" with a HostPort
+// set, following the same `prometheus.io/*` annotation convention
+// Prometheus' own Kubernetes service discovery uses. This is what
+// internal/metrics discovers to find the workload's endpoint. A "metrics"
+// port with no HostPort is only reachable inside the pod's network
+// namespace, not from the host running the scraper, so it's not a valid
+// scrape target and is left unannotated.
+func setPrometheusScrapeAnnotations(pod *v1.Pod) {
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Name != "metrics" || port.HostPort == 0 {
+				continue
+			}
+			if pod.Annotations == nil {
+				pod.Annotations = make(map[string]string)
+			}
+			pod.Annotations["prometheus.io/scrape"] = "true"
+			pod.Annotations["prometheus.io/port"] = strconv.Itoa(int(port.HostPort))
+			pod.Annotations["prometheus.io/path"] = "/metrics"
+			return
+		}
+	}
+}
+
+// scrapeTargetFromPod is the inverse of setPrometheusScrapeAnnotations: it
+// builds the metrics.Target internal/metrics scrapes from the annotations
+// toPod set. "prometheus.io/port" is always the published host port, so
+// localhost reaches it regardless of the pod's internal network namespace.
+func scrapeTargetFromPod(pod *v1.Pod) (metrics.Target, bool) {
+	if pod.Annotations["prometheus.io/scrape"] != "true" {
+		return metrics.Target{}, false
+	}
+	hostPort := pod.Annotations["prometheus.io/port"]
+	path := pod.Annotations["prometheus.io/path"]
+	if path == "" {
+		path = "/metrics"
+	}
+	return metrics.Target{
+		WorkloadName: pod.Name,
+		Address:      fmt.Sprintf("localhost:%s", hostPort),
+		Path:         path,
+	}, true
+}
+
 func (w *WorkloadManager) podModified(manifestPath string, podYaml []byte) bool {
 	file, err := ioutil.ReadFile(manifestPath)
 	if err != nil {
@@ -0,0 +1,64 @@
+package workload
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jakub-dzon/k4e-operator/models"
+)
+
+// TestUpdate_ResetsVolumePruneConfigWhenStorageBlockCleared verifies an
+// operator clearing the Storage block turns periodic volume pruning back
+// off rather than leaving the previous interval/max-age in effect.
+func TestUpdate_ResetsVolumePruneConfigWhenStorageBlockCleared(t *testing.T) {
+	w := newTestManager(t)
+
+	if err := w.Update(models.DeviceConfigurationMessage{
+		Configuration: &models.Configuration{
+			Storage: &models.StorageConfiguration{
+				VolumesPruneIntervalMinutes: 10,
+				VolumesPruneMaxUnusedHours:  24,
+			},
+		},
+	}); err != nil {
+		t.Fatalf("Update (with Storage): %v", err)
+	}
+	if w.volumePruneInterval != 10*time.Minute || w.volumePruneMaxAge != 24*time.Hour {
+		t.Fatalf("expected prune config to be applied, got interval=%v maxAge=%v", w.volumePruneInterval, w.volumePruneMaxAge)
+	}
+
+	if err := w.Update(models.DeviceConfigurationMessage{}); err != nil {
+		t.Fatalf("Update (Storage cleared): %v", err)
+	}
+	if w.volumePruneInterval != 0 || w.volumePruneMaxAge != 0 {
+		t.Fatalf("expected clearing Storage to reset prune config, got interval=%v maxAge=%v", w.volumePruneInterval, w.volumePruneMaxAge)
+	}
+}
+
+// TestUpdate_WorkloadsMonitoringIntervalIsSeconds verifies
+// WorkloadsMonitoringInterval is interpreted as seconds (matching
+// initTicker and the field's documented unit), not nanoseconds - a
+// regression test for a bug that reset the ticker to a sub-microsecond
+// busy loop instead of the requested cadence.
+func TestUpdate_WorkloadsMonitoringIntervalIsSeconds(t *testing.T) {
+	w := newTestManager(t)
+
+	if err := w.Update(models.DeviceConfigurationMessage{WorkloadsMonitoringInterval: 1}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	ticks := 0
+	timeout := time.After(150 * time.Millisecond)
+drain:
+	for {
+		select {
+		case <-w.ticker.C:
+			ticks++
+		case <-timeout:
+			break drain
+		}
+	}
+	if ticks != 0 {
+		t.Fatalf("expected a 1-second interval to not tick within 150ms, got %d ticks (interval misinterpreted as nanoseconds?)", ticks)
+	}
+}
@@ -0,0 +1,211 @@
+package workload
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"git.sr.ht/~spc/go-log"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// namedVolumeSourcePrefix marks a v1.Volume's HostPath.Path as a request for
+// a first-class named volume rather than a literal host path. toPod rewrites
+// it to the volume's real directory under volumesDir once materialized.
+//
+// PersistentVolumeClaim volumes are treated the same way, using the claim
+// name as the volume name, so operators can reuse either k8s idiom.
+const namedVolumeSourcePrefix = "named://"
+
+const volumeMetadataFileName = ".flotta-volume.json"
+
+// namedVolumeMetadata is persisted as volumesDir/<name>/.flotta-volume.json
+// so PruneVolumes can tell an unreferenced-but-recently-used volume from one
+// that's safe to reclaim.
+type namedVolumeMetadata struct {
+	Owners    []string  `json:"owners"`
+	CreatedAt time.Time `json:"createdAt"`
+	LastUsed  time.Time `json:"lastUsed"`
+}
+
+// PruneFilter selects which named volume directories PruneVolumes is
+// allowed to remove.
+type PruneFilter struct {
+	// MaxUnusedAge is how long a volume must have gone unreferenced by any
+	// manifest before it's eligible for removal. Zero means "no minimum
+	// age" - anything unreferenced is eligible.
+	MaxUnusedAge time.Duration
+}
+
+// namedVolumeRequest describes a volume materialization asked for by a pod,
+// resolved from either a "named://" HostPath or a PersistentVolumeClaim.
+func namedVolumeRequest(volume v1.Volume) (name string, ok bool) {
+	if volume.PersistentVolumeClaim != nil {
+		return volume.PersistentVolumeClaim.ClaimName, true
+	}
+	if volume.HostPath != nil && strings.HasPrefix(volume.HostPath.Path, namedVolumeSourcePrefix) {
+		return strings.TrimPrefix(volume.HostPath.Path, namedVolumeSourcePrefix), true
+	}
+	return "", false
+}
+
+// namedVolumeDir returns the on-disk directory a named volume materializes
+// under.
+func (w *WorkloadManager) namedVolumeDir(name string) string {
+	return path.Join(w.volumesDir, name)
+}
+
+// materializeNamedVolumes rewrites pod's named-volume requests (PVC or
+// "named://" HostPath) into real HostPath volumes backed by a directory
+// under volumesDir, creating that directory and its metadata file if this
+// is the first workload to reference it, or touching LastUsed/Owners if
+// not.
+func (w *WorkloadManager) materializeNamedVolumes(pod *v1.Pod) error {
+	for i, volume := range pod.Spec.Volumes {
+		name, ok := namedVolumeRequest(volume)
+		if !ok {
+			continue
+		}
+		dir := w.namedVolumeDir(name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("cannot create named volume '%s': %w", name, err)
+		}
+		if err := w.touchVolumeMetadata(dir, pod.Name); err != nil {
+			return fmt.Errorf("cannot update metadata for named volume '%s': %w", name, err)
+		}
+		pod.Spec.Volumes[i].VolumeSource = v1.VolumeSource{
+			HostPath: &v1.HostPathVolumeSource{Path: dir},
+		}
+	}
+	return nil
+}
+
+func (w *WorkloadManager) touchVolumeMetadata(dir, ownerWorkload string) error {
+	metadataPath := path.Join(dir, volumeMetadataFileName)
+	now := time.Now()
+	metadata := namedVolumeMetadata{CreatedAt: now}
+	if existing, err := ioutil.ReadFile(metadataPath); err == nil {
+		_ = json.Unmarshal(existing, &metadata)
+	}
+	metadata.LastUsed = now
+	if !containsString(metadata.Owners, ownerWorkload) {
+		metadata.Owners = append(metadata.Owners, ownerWorkload)
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(metadataPath, encoded, 0640)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// PruneVolumes removes named volume directories that are no longer
+// referenced by any stored manifest and have gone unused for at least
+// filter.MaxUnusedAge, mirroring `podman volume prune`. It returns the
+// names of the volumes it removed.
+func (w *WorkloadManager) PruneVolumes(filter PruneFilter) ([]string, error) {
+	w.managementLock.Lock()
+	defer w.managementLock.Unlock()
+	return w.pruneVolumesLocked(filter)
+}
+
+// pruneVolumesLocked is PruneVolumes' implementation, callable by code that
+// already holds managementLock (the monitoring goroutine).
+func (w *WorkloadManager) pruneVolumesLocked(filter PruneFilter) ([]string, error) {
+	referenced, err := w.referencedNamedVolumes()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine referenced volumes: %w", err)
+	}
+
+	entries, err := ioutil.ReadDir(w.volumesDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list volumes directory: %w", err)
+	}
+
+	var removed []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if _, ok := referenced[name]; ok {
+			continue
+		}
+		metadata, err := w.readVolumeMetadata(name)
+		if err != nil {
+			log.Errorf("cannot read metadata for volume '%s', skipping prune: %v", name, err)
+			continue
+		}
+		if time.Since(metadata.LastUsed) < filter.MaxUnusedAge {
+			continue
+		}
+		if err := os.RemoveAll(w.namedVolumeDir(name)); err != nil {
+			log.Errorf("cannot remove unused volume '%s': %v", name, err)
+			continue
+		}
+		log.Infof("Pruned unused volume '%s' (last used %s)", name, metadata.LastUsed)
+		removed = append(removed, name)
+	}
+	return removed, nil
+}
+
+func (w *WorkloadManager) readVolumeMetadata(name string) (namedVolumeMetadata, error) {
+	raw, err := ioutil.ReadFile(path.Join(w.namedVolumeDir(name), volumeMetadataFileName))
+	if err != nil {
+		return namedVolumeMetadata{}, err
+	}
+	var metadata namedVolumeMetadata
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return namedVolumeMetadata{}, err
+	}
+	return metadata, nil
+}
+
+// referencedNamedVolumes scans every stored manifest for named-volume
+// requests, so PruneVolumes never removes a volume a currently deployed
+// workload still depends on.
+func (w *WorkloadManager) referencedNamedVolumes() (map[string]struct{}, error) {
+	manifestInfo, err := ioutil.ReadDir(w.manifestsDir)
+	if err != nil {
+		return nil, err
+	}
+	referenced := make(map[string]struct{})
+	for _, fi := range manifestInfo {
+		manifest, err := ioutil.ReadFile(path.Join(w.manifestsDir, fi.Name()))
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		pod := v1.Pod{}
+		if err := yaml.Unmarshal(manifest, &pod); err != nil {
+			log.Error(err)
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if name, ok := namedVolumeRequest(volume); ok {
+				referenced[name] = struct{}{}
+				continue
+			}
+			// Already-materialized volumes were rewritten to a HostPath
+			// under volumesDir by materializeNamedVolumes; recognize those
+			// too so a second Update doesn't see them as unreferenced.
+			if volume.HostPath != nil && strings.HasPrefix(volume.HostPath.Path, w.volumesDir+"/") {
+				referenced[path.Base(volume.HostPath.Path)] = struct{}{}
+			}
+		}
+	}
+	return referenced, nil
+}
@@ -0,0 +1,119 @@
+package workload
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func newTestManager(t *testing.T) *WorkloadManager {
+	t.Helper()
+	w, err := NewWorkloadManagerWithParams(t.TempDir(), newFakeWorkloadWrapper(), "device-config", "/tmp/device-config", nil)
+	if err != nil {
+		t.Fatalf("NewWorkloadManagerWithParams: %v", err)
+	}
+	return w
+}
+
+func writeManifest(t *testing.T, w *WorkloadManager, workloadName string, volumes ...v1.Volume) {
+	t.Helper()
+	pod := &v1.Pod{}
+	pod.Name = workloadName
+	pod.Spec.Volumes = volumes
+	podYaml, err := yaml.Marshal(pod)
+	if err != nil {
+		t.Fatalf("yaml.Marshal: %v", err)
+	}
+	if err := w.storeManifest(w.getManifestPath(workloadName), podYaml); err != nil {
+		t.Fatalf("storeManifest: %v", err)
+	}
+}
+
+func namedVolume(name string) v1.Volume {
+	return v1.Volume{
+		VolumeSource: v1.VolumeSource{
+			HostPath: &v1.HostPathVolumeSource{Path: namedVolumeSourcePrefix + name},
+		},
+	}
+}
+
+// TestReferencedNamedVolumes_TracksBothRequestedAndMaterializedForms verifies
+// a volume is recognized as referenced whether the stored manifest still
+// carries the "named://" request or has already been rewritten to its
+// materialized HostPath by materializeNamedVolumes.
+func TestReferencedNamedVolumes_TracksBothRequestedAndMaterializedForms(t *testing.T) {
+	w := newTestManager(t)
+
+	writeManifest(t, w, "requested", namedVolume("cache"))
+	writeManifest(t, w, "materialized", v1.Volume{
+		VolumeSource: v1.VolumeSource{
+			HostPath: &v1.HostPathVolumeSource{Path: w.namedVolumeDir("logs")},
+		},
+	})
+
+	referenced, err := w.referencedNamedVolumes()
+	if err != nil {
+		t.Fatalf("referencedNamedVolumes: %v", err)
+	}
+	for _, name := range []string{"cache", "logs"} {
+		if _, ok := referenced[name]; !ok {
+			t.Fatalf("expected %q to be referenced, got %+v", name, referenced)
+		}
+	}
+}
+
+// TestPruneVolumesLocked_SkipsReferencedAndRecentlyUsed verifies a volume
+// survives pruning if either a manifest still references it or it hasn't
+// gone unused for MaxUnusedAge yet, and is only removed once both
+// conditions fail.
+func TestPruneVolumesLocked_SkipsReferencedAndRecentlyUsed(t *testing.T) {
+	w := newTestManager(t)
+
+	writeManifest(t, w, "keepme", namedVolume("referenced"))
+
+	for _, name := range []string{"referenced", "fresh", "stale"} {
+		dir := w.namedVolumeDir(name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("mkdir %s: %v", name, err)
+		}
+		if err := w.touchVolumeMetadata(dir, "some-owner"); err != nil {
+			t.Fatalf("touchVolumeMetadata %s: %v", name, err)
+		}
+	}
+	backdateVolumeMetadata(t, w, "stale", time.Now().Add(-48*time.Hour))
+
+	removed, err := w.pruneVolumesLocked(PruneFilter{MaxUnusedAge: time.Hour})
+	if err != nil {
+		t.Fatalf("pruneVolumesLocked: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "stale" {
+		t.Fatalf("expected only 'stale' to be pruned, got %+v", removed)
+	}
+	for _, name := range []string{"referenced", "fresh"} {
+		if _, err := ioutil.ReadDir(w.namedVolumeDir(name)); err != nil {
+			t.Fatalf("expected %q to survive pruning: %v", name, err)
+		}
+	}
+}
+
+func backdateVolumeMetadata(t *testing.T, w *WorkloadManager, name string, lastUsed time.Time) {
+	t.Helper()
+	metadata, err := w.readVolumeMetadata(name)
+	if err != nil {
+		t.Fatalf("readVolumeMetadata %s: %v", name, err)
+	}
+	metadata.LastUsed = lastUsed
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		t.Fatalf("marshal metadata: %v", err)
+	}
+	if err := ioutil.WriteFile(path.Join(w.namedVolumeDir(name), volumeMetadataFileName), encoded, 0640); err != nil {
+		t.Fatalf("write metadata: %v", err)
+	}
+}
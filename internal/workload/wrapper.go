@@ -0,0 +1,42 @@
+package workload
+
+import (
+	"context"
+
+	api2 "github.com/jakub-dzon/k4e-device-worker/internal/workload/api"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Observer is notified of workload state transitions WorkloadManager itself
+// detects (as opposed to ones WorkloadWrapper already reports through its
+// own RegisterObserver plumbing), such as a container crossing its
+// healthcheck retry threshold.
+type Observer interface {
+	OnWorkloadHealthChanged(workloadName, containerName string, healthy bool, log string)
+}
+
+// WorkloadWrapper is WorkloadManager's seam onto the container runtime
+// (podman, in production; a fake in tests). NewWorkloadManager backs it with
+// newWorkloadInstance; NewWorkloadManagerWithParams lets callers (tests,
+// alternate runtimes) supply their own.
+type WorkloadWrapper interface {
+	Init() error
+	List() ([]api2.WorkloadInfo, error)
+	Run(pod *v1.Pod, manifestPath string, configMapsPaths []string) error
+	Start(pod *v1.Pod) error
+	Remove(workloadName string) error
+	RemoveTable() error
+	RemoveMappingFile() error
+	PersistConfiguration() error
+	RegisterObserver(observer Observer)
+	// HealthCheck runs the named container's configured healthcheck exec
+	// and reports whether it passed.
+	HealthCheck(workloadName, containerName string) (healthy bool, log string, err error)
+	// Events streams container lifecycle events (died, stopped, oom,
+	// health_status) until ctx is cancelled.
+	Events(ctx context.Context) (<-chan WorkloadEvent, error)
+	// Copy moves a tar stream between hostPath on the host and containerPath
+	// in the named workload's container, in the direction given by dir.
+	// progress, if non-nil, is invoked as the stream advances.
+	Copy(workloadName, hostPath, containerPath string, dir CopyDirection, overwrite, preserveOwnership, noDereference bool, progress func(bytesCopied, totalBytes int64)) (CopyResult, error)
+}